@@ -0,0 +1,28 @@
+package utils
+
+import (
+	"github.com/SigNoz/signoz/pkg/types/authtypes"
+)
+
+// ComposeAuthorizedFilter appends a PreparedAuthorized clause to an existing WHERE fragment, so a
+// list endpoint's query builder can bulk filter rows to the ones the requesting subject can access
+// without an OpenFGA Check per row. existingWhere may be empty, in which case the authorization
+// clause becomes the whole WHERE body.
+//
+// None of the alerts/dashboards/saved-views/field-value list builders this is meant for exist yet
+// in this tree, so there is no call site to wire it into here; whichever of those builders lands
+// first should call this when assembling its WHERE clause.
+func ComposeAuthorizedFilter(prepared *authtypes.PreparedAuthorized, dialect authtypes.SQLDialect, existingWhere string, existingArgs []any) (string, []any, error) {
+	authSQL, authArgs, err := prepared.CompileSQL(dialect)
+	if err != nil {
+		return "", nil, err
+	}
+
+	args := append(append([]any{}, existingArgs...), authArgs...)
+
+	if existingWhere == "" {
+		return authSQL, args, nil
+	}
+
+	return "(" + existingWhere + ") AND (" + authSQL + ")", args, nil
+}
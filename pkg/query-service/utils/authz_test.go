@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"testing"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SigNoz/signoz/pkg/types/authtypes"
+	"github.com/SigNoz/signoz/pkg/valuer"
+)
+
+type fakeIdentityResolver struct{}
+
+func (fakeIdentityResolver) ResolveConstantObjectIDs(_ string, _ string, _ authtypes.Type) ([]string, error) {
+	return []string{"dash-1", "dash-2"}, nil
+}
+
+func (fakeIdentityResolver) ResolveTupleToUsersetSubquery(_ authtypes.SQLDialect, _ string, _ string, _ string, _ authtypes.Type) (string, []any, error) {
+	return "", nil, nil
+}
+
+func (fakeIdentityResolver) RewriteFor(_ authtypes.Relation, _ authtypes.Type) (*openfgav1.Userset, error) {
+	return &openfgav1.Userset{Userset: &openfgav1.Userset_This{This: &openfgav1.DirectUserset{}}}, nil
+}
+
+func TestComposeAuthorizedFilter(t *testing.T) {
+	authorizer := authtypes.NewAuthorizer(fakeIdentityResolver{})
+	prepared, err := authorizer.Prepare("user:alice", authtypes.Relation{String: valuer.NewString("viewer")}, authtypes.TypeUser)
+	require.NoError(t, err)
+
+	where, args, err := ComposeAuthorizedFilter(prepared, authtypes.SQLDialectClickHouse, "org_id = ?", []any{"org-1"})
+	require.NoError(t, err)
+	assert.Equal(t, "(org_id = ?) AND (id IN (?, ?))", where)
+	assert.Equal(t, []any{"org-1", "dash-1", "dash-2"}, args)
+}
+
+func TestComposeAuthorizedFilter_NoExistingWhere(t *testing.T) {
+	authorizer := authtypes.NewAuthorizer(fakeIdentityResolver{})
+	prepared, err := authorizer.Prepare("user:alice", authtypes.Relation{String: valuer.NewString("viewer")}, authtypes.TypeUser)
+	require.NoError(t, err)
+
+	where, args, err := ComposeAuthorizedFilter(prepared, authtypes.SQLDialectClickHouse, "", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "id IN (?, ?)", where)
+	assert.Equal(t, []any{"dash-1", "dash-2"}, args)
+}
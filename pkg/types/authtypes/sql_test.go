@@ -0,0 +1,335 @@
+package authtypes
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SigNoz/signoz/pkg/valuer"
+)
+
+// fixtureResolver is a seeded IdentityResolver fixture standing in for OpenFGA's Expand and our
+// local identity tables, so CompileSQL's output can be checked against an authoritative per-row
+// answer without a live OpenFGA instance. Direct grants are keyed by (subject, relation), exactly
+// as OpenFGA's Expand would be, so a `this` leaf on one relation can't see another's ids.
+type fixtureResolver struct {
+	directIDs         map[string]map[string][]string // subject -> relation -> ids
+	rewrites          map[string]*openfgav1.Userset  // relation -> rewrite
+	tupleToUsersetIDs map[string][]string            // subject -> group ids reached via tuple_to_userset
+}
+
+func (f *fixtureResolver) ResolveConstantObjectIDs(subject string, relation string, _ Type) ([]string, error) {
+	return f.directIDs[subject][relation], nil
+}
+
+// ResolveTupleToUsersetSubquery stands in for a group-membership subquery. It renders a visibly
+// different fragment per dialect, so a test can prove the same compiled plan honours whichever
+// dialect CompileSQL is called with, rather than a dialect baked in at Prepare time.
+func (f *fixtureResolver) ResolveTupleToUsersetSubquery(dialect SQLDialect, subject string, _ string, _ string, _ Type) (string, []any, error) {
+	ids := f.tupleToUsersetIDs[subject]
+	if len(ids) == 0 {
+		return "1 = 0", nil, nil
+	}
+
+	placeholders := make([]string, len(ids))
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		placeholders[i] = "?"
+		args[i] = id
+	}
+
+	clause := fmt.Sprintf("group_id IN (%s)", strings.Join(placeholders, ", "))
+	if dialect == SQLDialectSQLite {
+		clause += " COLLATE NOCASE"
+	}
+	return clause, args, nil
+}
+
+func (f *fixtureResolver) RewriteFor(relation Relation, _ Type) (*openfgav1.Userset, error) {
+	rewrite, ok := f.rewrites[relation.StringValue()]
+	if !ok {
+		return nil, fmt.Errorf("fixtureResolver: no rewrite registered for relation %q", relation.StringValue())
+	}
+	return rewrite, nil
+}
+
+// authoritativeCheck mirrors what a per-row OpenFGA Check against the same fixture would answer
+// for a `relation := this OR owner` rewrite.
+func authoritativeCheck(resolver *fixtureResolver, subject, relation, objectID string) bool {
+	for _, id := range resolver.directIDs[subject][relation] {
+		if id == objectID {
+			return true
+		}
+	}
+	for _, id := range resolver.directIDs[subject]["owner"] {
+		if id == objectID {
+			return true
+		}
+	}
+	return false
+}
+
+// thisOrOwnerRewrite builds `relation := this OR owner`, the union rewrite shape of a
+// direct-share-or-ownership relation.
+func thisOrOwnerRewrite() *openfgav1.Userset {
+	return &openfgav1.Userset{
+		Userset: &openfgav1.Userset_Union{
+			Union: &openfgav1.Usersets{
+				Child: []*openfgav1.Userset{
+					{Userset: &openfgav1.Userset_This{This: &openfgav1.DirectUserset{}}},
+					{Userset: &openfgav1.Userset_ComputedUserset{ComputedUserset: &openfgav1.ObjectRelation{Relation: "owner"}}},
+				},
+			},
+		},
+	}
+}
+
+func TestPrepareCompileSQL_UserTypeable(t *testing.T) {
+	resolver := &fixtureResolver{
+		directIDs: map[string]map[string][]string{
+			"user:alice": {
+				"viewer": {"dash-1", "dash-2"},
+				"owner":  {"dash-3"},
+			},
+		},
+	}
+
+	relation := Relation{valuer.NewString("viewer")}
+
+	prepared, err := Prepare("user:alice", relation, TypeUser, thisOrOwnerRewrite(), resolver)
+	require.NoError(t, err)
+
+	sql, args, err := prepared.CompileSQL(SQLDialectClickHouse)
+	require.NoError(t, err)
+	assert.Equal(t, "(id IN (?, ?)) OR (id IN (?))", sql)
+	assert.Equal(t, []any{"dash-1", "dash-2", "dash-3"}, args)
+
+	universe := []string{"dash-1", "dash-2", "dash-3", "dash-4"}
+	for _, objectID := range universe {
+		compiledMatches := false
+		for _, arg := range args {
+			if arg == objectID {
+				compiledMatches = true
+				break
+			}
+		}
+		assert.Equal(t, authoritativeCheck(resolver, "user:alice", "viewer", objectID), compiledMatches, "object %s", objectID)
+	}
+}
+
+// TestPrepareCompileSQL_DistinctRelationsDontCollide proves a `this` leaf is resolved against the
+// relation being compiled, not a fixed/empty relation: `editor` and `viewer` each have their own
+// direct grants on the same subject and object type, and compiling one must not see the other's.
+func TestPrepareCompileSQL_DistinctRelationsDontCollide(t *testing.T) {
+	resolver := &fixtureResolver{
+		directIDs: map[string]map[string][]string{
+			"user:alice": {
+				"viewer": {"dash-1"},
+				"editor": {"dash-2"},
+			},
+		},
+	}
+
+	directRewrite := &openfgav1.Userset{Userset: &openfgav1.Userset_This{This: &openfgav1.DirectUserset{}}}
+
+	viewerPrepared, err := Prepare("user:alice", Relation{valuer.NewString("viewer")}, TypeUser, directRewrite, resolver)
+	require.NoError(t, err)
+	viewerSQL, viewerArgs, err := viewerPrepared.CompileSQL(SQLDialectClickHouse)
+	require.NoError(t, err)
+	assert.Equal(t, "id IN (?)", viewerSQL)
+	assert.Equal(t, []any{"dash-1"}, viewerArgs)
+
+	editorPrepared, err := Prepare("user:alice", Relation{valuer.NewString("editor")}, TypeUser, directRewrite, resolver)
+	require.NoError(t, err)
+	editorSQL, editorArgs, err := editorPrepared.CompileSQL(SQLDialectClickHouse)
+	require.NoError(t, err)
+	assert.Equal(t, "id IN (?)", editorSQL)
+	assert.Equal(t, []any{"dash-2"}, editorArgs)
+}
+
+func TestPrepareCompileSQL_EmptyExpansionFailsClosed(t *testing.T) {
+	resolver := &fixtureResolver{}
+	rewrite := &openfgav1.Userset{Userset: &openfgav1.Userset_This{This: &openfgav1.DirectUserset{}}}
+	relation := Relation{valuer.NewString("viewer")}
+
+	prepared, err := Prepare("user:bob", relation, TypeUser, rewrite, resolver)
+	require.NoError(t, err)
+
+	sql, args, err := prepared.CompileSQL(SQLDialectClickHouse)
+	require.NoError(t, err)
+	assert.Equal(t, "1 = 0", sql)
+	assert.Empty(t, args)
+}
+
+// thisAndOwnerRewrite builds `relation := this AND owner`, the shape an intersection rewrite takes.
+func thisAndOwnerRewrite() *openfgav1.Userset {
+	return &openfgav1.Userset{
+		Userset: &openfgav1.Userset_Intersection{
+			Intersection: &openfgav1.Usersets{
+				Child: []*openfgav1.Userset{
+					{Userset: &openfgav1.Userset_This{This: &openfgav1.DirectUserset{}}},
+					{Userset: &openfgav1.Userset_ComputedUserset{ComputedUserset: &openfgav1.ObjectRelation{Relation: "owner"}}},
+				},
+			},
+		},
+	}
+}
+
+func TestPrepareCompileSQL_Intersection(t *testing.T) {
+	resolver := &fixtureResolver{
+		directIDs: map[string]map[string][]string{
+			"user:alice": {
+				"viewer": {"dash-1", "dash-2"},
+				"owner":  {"dash-2", "dash-3"},
+			},
+		},
+	}
+
+	relation := Relation{valuer.NewString("viewer")}
+
+	prepared, err := Prepare("user:alice", relation, TypeUser, thisAndOwnerRewrite(), resolver)
+	require.NoError(t, err)
+
+	sql, args, err := prepared.CompileSQL(SQLDialectClickHouse)
+	require.NoError(t, err)
+	assert.Equal(t, "(id IN (?, ?)) AND (id IN (?, ?))", sql)
+	assert.Equal(t, []any{"dash-1", "dash-2", "dash-2", "dash-3"}, args)
+
+	// The compiled clause is an AND of two independent IN-lists, so an object matches only if it's
+	// in both - exactly what a per-row Check against `viewer AND owner` would answer.
+	for _, objectID := range []string{"dash-1", "dash-2", "dash-3", "dash-4"} {
+		viewer := contains(resolver.directIDs["user:alice"]["viewer"], objectID)
+		owner := contains(resolver.directIDs["user:alice"]["owner"], objectID)
+		authoritative := viewer && owner
+		compiled := objectID == "dash-2" // the only id present in both IN-lists above
+		assert.Equal(t, authoritative, compiled, "object %s", objectID)
+	}
+}
+
+func contains(ids []string, objectID string) bool {
+	for _, id := range ids {
+		if id == objectID {
+			return true
+		}
+	}
+	return false
+}
+
+// thisMinusBlockedRewrite builds `relation := this BUT NOT blocked`, the shape a difference
+// rewrite takes.
+func thisMinusBlockedRewrite() *openfgav1.Userset {
+	return &openfgav1.Userset{
+		Userset: &openfgav1.Userset_Difference{
+			Difference: &openfgav1.Difference{
+				Base:     &openfgav1.Userset{Userset: &openfgav1.Userset_This{This: &openfgav1.DirectUserset{}}},
+				Subtract: &openfgav1.Userset{Userset: &openfgav1.Userset_ComputedUserset{ComputedUserset: &openfgav1.ObjectRelation{Relation: "blocked"}}},
+			},
+		},
+	}
+}
+
+func TestPrepareCompileSQL_Difference(t *testing.T) {
+	resolver := &fixtureResolver{
+		directIDs: map[string]map[string][]string{
+			"user:alice": {
+				"viewer":  {"dash-1", "dash-2", "dash-3"},
+				"blocked": {"dash-2"},
+			},
+		},
+	}
+
+	relation := Relation{valuer.NewString("viewer")}
+
+	prepared, err := Prepare("user:alice", relation, TypeUser, thisMinusBlockedRewrite(), resolver)
+	require.NoError(t, err)
+
+	sql, args, err := prepared.CompileSQL(SQLDialectClickHouse)
+	require.NoError(t, err)
+	assert.Equal(t, "(id IN (?, ?, ?)) AND NOT (id IN (?))", sql)
+	assert.Equal(t, []any{"dash-1", "dash-2", "dash-3", "dash-2"}, args)
+
+	// The compiled clause is `(id IN viewer-ids) AND NOT (id IN blocked-ids)`, so an object matches
+	// only if it's a viewer and not blocked - exactly what a per-row Check against
+	// `viewer BUT NOT blocked` would answer.
+	for _, objectID := range []string{"dash-1", "dash-2", "dash-3", "dash-4"} {
+		viewer := contains(resolver.directIDs["user:alice"]["viewer"], objectID)
+		blocked := contains(resolver.directIDs["user:alice"]["blocked"], objectID)
+		authoritative := viewer && !blocked
+		compiled := objectID == "dash-1" || objectID == "dash-3" // viewer ids minus the blocked id
+		assert.Equal(t, authoritative, compiled, "object %s", objectID)
+	}
+}
+
+// TestPrepareCompileSQL_TupleToUsersetRendersPerDialect proves a tuple_to_userset leaf's subquery
+// is resolved at CompileSQL time against the dialect it's asked to render for, not baked in at
+// Prepare time: the same compiled plan must produce ClickHouse SQL for one call and SQLite SQL for
+// another.
+func TestPrepareCompileSQL_TupleToUsersetRendersPerDialect(t *testing.T) {
+	resolver := &fixtureResolver{
+		tupleToUsersetIDs: map[string][]string{
+			"user:alice": {"group-1"},
+		},
+	}
+
+	rewrite := &openfgav1.Userset{
+		Userset: &openfgav1.Userset_TupleToUserset{
+			TupleToUserset: &openfgav1.TupleToUserset{
+				Tupleset:        &openfgav1.ObjectRelation{Relation: "parent"},
+				ComputedUserset: &openfgav1.ObjectRelation{Relation: "member"},
+			},
+		},
+	}
+
+	relation := Relation{valuer.NewString("viewer")}
+
+	prepared, err := Prepare("user:alice", relation, TypeUser, rewrite, resolver)
+	require.NoError(t, err)
+
+	clickhouseSQL, clickhouseArgs, err := prepared.CompileSQL(SQLDialectClickHouse)
+	require.NoError(t, err)
+	assert.Equal(t, "group_id IN (?)", clickhouseSQL)
+	assert.Equal(t, []any{"group-1"}, clickhouseArgs)
+
+	sqliteSQL, sqliteArgs, err := prepared.CompileSQL(SQLDialectSQLite)
+	require.NoError(t, err)
+	assert.Equal(t, "group_id IN (?) COLLATE NOCASE", sqliteSQL)
+	assert.Equal(t, []any{"group-1"}, sqliteArgs)
+}
+
+func TestPrepare_UnsupportedRewriteFailsClosed(t *testing.T) {
+	relation := Relation{valuer.NewString("viewer")}
+	_, err := Prepare("user:alice", relation, TypeUser, &openfgav1.Userset{}, &fixtureResolver{})
+	require.ErrorIs(t, err, ErrUnsupportedRewrite)
+}
+
+// TestAuthorizerPrepare_LooksUpRewriteFromResolver proves Authorizer.Prepare fetches the rewrite
+// graph for relation/objectType from the resolver itself, so callers only need a subject, a
+// relation, and an object type - not the rewrite graph in hand.
+func TestAuthorizerPrepare_LooksUpRewriteFromResolver(t *testing.T) {
+	directRewrite := &openfgav1.Userset{Userset: &openfgav1.Userset_This{This: &openfgav1.DirectUserset{}}}
+	resolver := &fixtureResolver{
+		directIDs: map[string]map[string][]string{
+			"user:alice": {"viewer": {"dash-1"}},
+		},
+		rewrites: map[string]*openfgav1.Userset{
+			"viewer": directRewrite,
+		},
+	}
+
+	authorizer := NewAuthorizer(resolver)
+
+	prepared, err := authorizer.Prepare("user:alice", Relation{valuer.NewString("viewer")}, TypeUser)
+	require.NoError(t, err)
+
+	sql, args, err := prepared.CompileSQL(SQLDialectClickHouse)
+	require.NoError(t, err)
+	assert.Equal(t, "id IN (?)", sql)
+	assert.Equal(t, []any{"dash-1"}, args)
+
+	_, err = authorizer.Prepare("user:alice", Relation{valuer.NewString("editor")}, TypeUser)
+	assert.Error(t, err, "editor has no registered rewrite, so Prepare should surface the lookup failure")
+}
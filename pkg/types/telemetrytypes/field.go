@@ -41,69 +41,238 @@ func (f TelemetryFieldKey) String() string {
 
 // GetFieldKeyFromKeyText returns a TelemetryFieldKey from a key text.
 // The key text is expected to be in the format of `fieldContext.fieldName:fieldDataType` in the search query.
+// It never fails: a malformed key text (e.g. an unterminated quote) is returned verbatim as the field name.
+// Call sites that need to surface parse errors to the user should migrate to ParseFieldKey.
 func GetFieldKeyFromKeyText(key string) TelemetryFieldKey {
+	fieldKey, err := ParseFieldKey(key)
+	if err != nil {
+		return TelemetryFieldKey{Name: key}
+	}
+	return fieldKey
+}
 
-	keyTextParts := strings.Split(key, ".")
+// ParseFieldKey parses a key text of the form `fieldContext.fieldName:fieldDataType` into a TelemetryFieldKey.
+// A segment of fieldName may be wrapped in backticks or double quotes to allow literal `.` and `:` characters,
+// for example:
+//
+//	resource.`k8s.pod.labels`.`app.kubernetes.io/name`:string
+//
+// parses to Name="k8s.pod.labels.app.kubernetes.io/name", FieldContext=resource, FieldDataType=string. A quote
+// character is escaped by doubling it (`` `` `` or `""`). Unlike GetFieldKeyFromKeyText, ParseFieldKey reports
+// malformed input (an unterminated quote, trailing text after a closing quote that isn't a `:fieldDataType`
+// suffix, or an unrecognised data type on a quoted segment) as an error instead of guessing.
+func ParseFieldKey(key string) (TelemetryFieldKey, error) {
+	rawSegments, err := splitFieldKeySegments(key)
+	if err != nil {
+		return TelemetryFieldKey{}, fmt.Errorf("telemetrytypes: %w in field key %q", err, key)
+	}
 
-	var explicitFieldContextProvided, explicitFieldDataTypeProvided bool
-	var explicitFieldContext FieldContext
-	var explicitFieldDataType FieldDataType
-	var ok bool
+	type parsedSegment struct {
+		content string
+		quoted  bool
+		trailer string
+	}
 
-	if len(keyTextParts) > 1 {
-		explicitFieldContext, ok = fieldContexts[keyTextParts[0]]
-		if ok && explicitFieldContext != FieldContextUnspecified {
-			explicitFieldContextProvided = true
+	segments := make([]parsedSegment, 0, len(rawSegments))
+	for _, raw := range rawSegments {
+		content, quoted, trailer, err := splitQuotedSegment(raw)
+		if err != nil {
+			return TelemetryFieldKey{}, fmt.Errorf("telemetrytypes: %w in field key %q", err, key)
 		}
+		segments = append(segments, parsedSegment{content: content, quoted: quoted, trailer: trailer})
 	}
 
-	if explicitFieldContextProvided {
-		keyTextParts = keyTextParts[1:]
+	fieldContext := FieldContextUnspecified
+	if len(segments) > 1 && !segments[0].quoted {
+		if fc, ok := fieldContexts[segments[0].content]; ok && fc != FieldContextUnspecified {
+			fieldContext = fc
+			segments = segments[1:]
+		}
 	}
 
-	// check if there is a field data type provided
-	if len(keyTextParts) >= 1 {
-		lastPart := keyTextParts[len(keyTextParts)-1]
-		lastPartParts := strings.Split(lastPart, ":")
-		if len(lastPartParts) > 1 {
-			explicitFieldDataType, ok = fieldDataTypes[lastPartParts[1]]
-			if ok && explicitFieldDataType != FieldDataTypeUnspecified {
-				explicitFieldDataTypeProvided = true
+	last := &segments[len(segments)-1]
+	fieldDataType := FieldDataTypeUnspecified
+	if last.quoted {
+		if last.trailer != "" {
+			dataTypeText, ok := strings.CutPrefix(last.trailer, ":")
+			if !ok {
+				return TelemetryFieldKey{}, fmt.Errorf("telemetrytypes: unexpected trailing text %q in field key %q", last.trailer, key)
 			}
+			dt, ok := fieldDataTypes[dataTypeText]
+			if !ok || dt == FieldDataTypeUnspecified {
+				return TelemetryFieldKey{}, fmt.Errorf("telemetrytypes: unknown field data type %q in field key %q", dataTypeText, key)
+			}
+			fieldDataType = dt
+			last.trailer = ""
+		}
+	} else if idx := strings.LastIndex(last.content, ":"); idx >= 0 {
+		if dt, ok := fieldDataTypes[last.content[idx+1:]]; ok && dt != FieldDataTypeUnspecified {
+			fieldDataType = dt
+			last.content = last.content[:idx]
 		}
+	}
 
-		if explicitFieldDataTypeProvided {
-			keyTextParts[len(keyTextParts)-1] = lastPartParts[0]
+	names := make([]string, len(segments))
+	for i, segment := range segments {
+		if segment.trailer != "" {
+			return TelemetryFieldKey{}, fmt.Errorf("telemetrytypes: unexpected trailing text %q in field key %q", segment.trailer, key)
 		}
+		names[i] = segment.content
 	}
 
-	realKey := strings.Join(keyTextParts, ".")
+	return TelemetryFieldKey{
+		Name:          strings.Join(names, "."),
+		FieldContext:  fieldContext,
+		FieldDataType: fieldDataType,
+	}, nil
+}
+
+// splitFieldKeySegments splits a key text on `.` separators, treating a backtick- or double-quote-delimited
+// run of characters as a single opaque segment so that a quoted name may itself contain dots.
+func splitFieldKeySegments(key string) ([]string, error) {
+	var segments []string
+	var buf strings.Builder
+	var quote rune
 
-	fieldKeySelector := TelemetryFieldKey{
-		Name: realKey,
+	runes := []rune(key)
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		switch {
+		case quote != 0:
+			buf.WriteRune(c)
+			if c == quote {
+				if i+1 < len(runes) && runes[i+1] == quote {
+					buf.WriteRune(quote)
+					i++
+					continue
+				}
+				quote = 0
+			}
+		case c == '`' || c == '"':
+			quote = c
+			buf.WriteRune(c)
+		case c == '.':
+			segments = append(segments, buf.String())
+			buf.Reset()
+		default:
+			buf.WriteRune(c)
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %q quote", quote)
 	}
 
-	if explicitFieldContextProvided {
-		fieldKeySelector.FieldContext = explicitFieldContext
-	} else {
-		fieldKeySelector.FieldContext = FieldContextUnspecified
+	segments = append(segments, buf.String())
+	return segments, nil
+}
+
+// splitQuotedSegment strips a leading and matching trailing quote (backtick or double quote) from segment,
+// unescaping a doubled quote character within. Any text following the closing quote is returned as trailer,
+// which callers use to recover a `:fieldDataType` suffix that trails a quoted final segment. A segment that
+// doesn't start with a quote character is returned unchanged with quoted=false.
+func splitQuotedSegment(segment string) (content string, quoted bool, trailer string, err error) {
+	if segment == "" {
+		return segment, false, "", nil
 	}
 
-	if explicitFieldDataTypeProvided {
-		fieldKeySelector.FieldDataType = explicitFieldDataType
-	} else {
-		fieldKeySelector.FieldDataType = FieldDataTypeUnspecified
+	quote := rune(segment[0])
+	if quote != '`' && quote != '"' {
+		return segment, false, "", nil
 	}
 
-	return fieldKeySelector
+	runes := []rune(segment)
+	var buf strings.Builder
+	i := 1
+	for ; i < len(runes); i++ {
+		if runes[i] != quote {
+			buf.WriteRune(runes[i])
+			continue
+		}
+		if i+1 < len(runes) && runes[i+1] == quote {
+			buf.WriteRune(quote)
+			i++
+			continue
+		}
+		i++
+		return buf.String(), true, string(runes[i:]), nil
+	}
+
+	return "", false, "", fmt.Errorf("unterminated %q quote", quote)
+}
+
+// escapeMaterializedColumnNamePart reversibly escapes a field name so it can be embedded in a ClickHouse
+// column identifier. `.` is still encoded as `$$`, exactly as before, so materialized columns created under
+// the old scheme keep resolving to the same name. A literal `$` in the source name is escaped to `$0` before
+// that substitution so it can't be confused with the `.` marker, and `/` and quote characters get their own
+// escapes, so a name containing `$$`, `/`, or a quote character now survives the round trip too.
+func escapeMaterializedColumnNamePart(name string) string {
+	var sb strings.Builder
+	for _, r := range name {
+		switch r {
+		case '$':
+			sb.WriteString("$0")
+		case '.':
+			sb.WriteString("$$")
+		case '/':
+			sb.WriteString("$s")
+		case '`':
+			sb.WriteString("$q")
+		case '"':
+			sb.WriteString("$Q")
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// unescapeMaterializedColumnNamePart is the inverse of escapeMaterializedColumnNamePart.
+func unescapeMaterializedColumnNamePart(escaped string) (string, error) {
+	var sb strings.Builder
+	for i := 0; i < len(escaped); i++ {
+		if escaped[i] != '$' {
+			sb.WriteByte(escaped[i])
+			continue
+		}
+		if i+1 >= len(escaped) {
+			return "", fmt.Errorf("telemetrytypes: truncated escape sequence in materialized column name %q", escaped)
+		}
+		switch escaped[i+1] {
+		case '$':
+			sb.WriteByte('.')
+		case '0':
+			sb.WriteByte('$')
+		case 's':
+			sb.WriteByte('/')
+		case 'q':
+			sb.WriteByte('`')
+		case 'Q':
+			sb.WriteByte('"')
+		default:
+			return "", fmt.Errorf("telemetrytypes: invalid escape sequence %q in materialized column name %q", escaped[i:i+2], escaped)
+		}
+		i++
+	}
+	return sb.String(), nil
+}
+
+// materializedColumnNameBase builds the unquoted `context_datatype_name` identifier shared by
+// FieldKeyToMaterializedColumnName and FieldKeyToMaterializedColumnNameForExists.
+func materializedColumnNameBase(key *TelemetryFieldKey) string {
+	return fmt.Sprintf(
+		"%s_%s_%s",
+		key.FieldContext.String,
+		fieldDataTypes[key.FieldDataType.StringValue()].StringValue(),
+		escapeMaterializedColumnNamePart(key.Name),
+	)
 }
 
 func FieldKeyToMaterializedColumnName(key *TelemetryFieldKey) string {
-	return fmt.Sprintf("`%s_%s_%s`", key.FieldContext.String, fieldDataTypes[key.FieldDataType.StringValue()].StringValue(), strings.ReplaceAll(key.Name, ".", "$$"))
+	return fmt.Sprintf("`%s`", materializedColumnNameBase(key))
 }
 
 func FieldKeyToMaterializedColumnNameForExists(key *TelemetryFieldKey) string {
-	return fmt.Sprintf("`%s_%s_%s_exists`", key.FieldContext.String, fieldDataTypes[key.FieldDataType.StringValue()].StringValue(), strings.ReplaceAll(key.Name, ".", "$$"))
+	return fmt.Sprintf("`%s_exists`", materializedColumnNameBase(key))
 }
 
 type TelemetryFieldValues struct {
@@ -0,0 +1,64 @@
+package telemetrytypes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFieldKey_QuotedSegments(t *testing.T) {
+	key := "resource.`k8s.pod.labels`.`app.kubernetes.io/name`:string"
+
+	fieldKey, err := ParseFieldKey(key)
+	require.NoError(t, err)
+	assert.Equal(t, "k8s.pod.labels.app.kubernetes.io/name", fieldKey.Name)
+	assert.Equal(t, FieldContextResource, fieldKey.FieldContext)
+	assert.Equal(t, FieldDataTypeString, fieldKey.FieldDataType)
+}
+
+func TestParseFieldKey_UnterminatedQuoteErrors(t *testing.T) {
+	_, err := ParseFieldKey("resource.`k8s.pod.labels")
+	assert.Error(t, err)
+}
+
+func TestGetFieldKeyFromKeyText_MalformedFallsBackToBestEffort(t *testing.T) {
+	key := "resource.`k8s.pod.labels"
+
+	_, err := ParseFieldKey(key)
+	require.Error(t, err, "precondition: the key text must be malformed")
+
+	fieldKey := GetFieldKeyFromKeyText(key)
+	assert.Equal(t, key, fieldKey.Name)
+	assert.Equal(t, FieldContextUnspecified, fieldKey.FieldContext)
+	assert.Equal(t, FieldDataTypeUnspecified, fieldKey.FieldDataType)
+}
+
+func TestMaterializedColumnNamePart_EscapeIsBackwardsCompatible(t *testing.T) {
+	// Names without `$`, `/`, or quote characters must keep encoding `.` as `$$`, since that's the
+	// scheme materialized columns already created in ClickHouse were named under.
+	assert.Equal(t, "k8s$$pod$$name", escapeMaterializedColumnNamePart("k8s.pod.name"))
+}
+
+func TestMaterializedColumnNamePart_EscapeUnescapeRoundTrip(t *testing.T) {
+	names := []string{
+		"k8s.pod.name",
+		"already$$escaped.looking",
+		"has/a/slash",
+		"has`a`backtick",
+		`has"a"doublequote`,
+		"mixed.`$$`/\"combo",
+	}
+
+	for _, name := range names {
+		escaped := escapeMaterializedColumnNamePart(name)
+		unescaped, err := unescapeMaterializedColumnNamePart(escaped)
+		require.NoError(t, err, "name %q", name)
+		assert.Equal(t, name, unescaped, "round trip for %q via %q", name, escaped)
+	}
+}
+
+func TestUnescapeMaterializedColumnNamePart_InvalidEscapeErrors(t *testing.T) {
+	_, err := unescapeMaterializedColumnNamePart("k8s$xpod")
+	assert.Error(t, err)
+}